@@ -0,0 +1,240 @@
+package progress
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultBacklogSize is the number of most recent Progress events the
+	// ring buffer holds. A new subscriber is replayed these, collapsed to
+	// the latest event per ID, before switching to live delivery.
+	defaultBacklogSize = 100
+	// defaultSubscriberTimeout is how long Broadcaster waits for a
+	// subscriber to accept an event before dropping it.
+	defaultSubscriberTimeout = 5 * time.Second
+)
+
+// Broadcaster fans out the Progress events observed on a Reader to any
+// number of subscribers that may join after progress has already started.
+// It keeps a fixed-size ring buffer of recently observed events so a late
+// subscriber can catch up without racing the primary Reader, and it drops
+// any subscriber that fails to keep up within a configurable timeout so one
+// stuck consumer can't stall the rest.
+type Broadcaster struct {
+	mu      sync.Mutex
+	ring    []*Progress
+	ringPos int
+	ringLen int
+	subs    map[*subscriber]struct{}
+	timeout time.Duration
+	closed  bool
+}
+
+// subscriber serializes every send to ch behind sendMu so that backlog
+// replay (Subscribe's goroutine) and live delivery (broadcast) can never
+// write to ch concurrently, and so ch is only ever closed by whichever
+// goroutine currently holds sendMu. Subscribe locks sendMu itself before
+// the subscriber is registered and hands the lock off to the backlog
+// goroutine, so live delivery always blocks behind replay for a freshly
+// subscribed subscriber.
+type subscriber struct {
+	ch     chan *Progress
+	sendMu sync.Mutex
+	closed bool
+}
+
+// NewBroadcaster starts draining r in the background and returns a
+// Broadcaster that fans its events out to subscribers. backlogSize and
+// timeout fall back to sane defaults when zero.
+func NewBroadcaster(r Reader, backlogSize int, timeout time.Duration) *Broadcaster {
+	if backlogSize <= 0 {
+		backlogSize = defaultBacklogSize
+	}
+	if timeout <= 0 {
+		timeout = defaultSubscriberTimeout
+	}
+	b := &Broadcaster{
+		ring:    make([]*Progress, backlogSize),
+		subs:    make(map[*subscriber]struct{}),
+		timeout: timeout,
+	}
+	go b.run(r)
+	return b
+}
+
+func (b *Broadcaster) run(r Reader) {
+	ctx := context.Background()
+	for {
+		ps, err := r.Read(ctx)
+		if err != nil {
+			b.Close()
+			return
+		}
+		b.broadcast(ps)
+	}
+}
+
+func (b *Broadcaster) broadcast(ps []*Progress) {
+	b.mu.Lock()
+	for _, p := range ps {
+		b.ring[b.ringPos] = p
+		b.ringPos = (b.ringPos + 1) % len(b.ring)
+		if b.ringLen < len(b.ring) {
+			b.ringLen++
+		}
+	}
+	subs := make([]*subscriber, 0, len(b.subs))
+	for s := range b.subs {
+		subs = append(subs, s)
+	}
+	b.mu.Unlock()
+
+	for _, s := range subs {
+		for _, p := range ps {
+			b.send(s, p)
+		}
+	}
+}
+
+// send delivers p to s, dropping s if it doesn't accept the event within
+// the broadcaster's timeout. It holds s.sendMu for the duration of the
+// attempt so that backlog replay and live delivery for the same
+// subscriber are always serialized, and so ch is never closed while
+// another goroutine is still sending to it.
+func (b *Broadcaster) send(s *subscriber, p *Progress) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	b.sendLocked(s, p)
+}
+
+// sendLocked is send's implementation; callers must already hold
+// s.sendMu, which lets Subscribe hold it across its entire backlog
+// replay loop so live delivery can never interleave with or get ahead
+// of replay for the same subscriber.
+func (b *Broadcaster) sendLocked(s *subscriber, p *Progress) {
+	if s.closed {
+		return
+	}
+	select {
+	case s.ch <- p:
+	case <-time.After(b.timeout):
+		b.dropLocked(s)
+	}
+}
+
+// drop unregisters s and closes its channel, serialized against send.
+func (b *Broadcaster) drop(s *subscriber) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	b.dropLocked(s)
+}
+
+// dropLocked does the work of drop; callers must already hold s.sendMu.
+func (b *Broadcaster) dropLocked(s *subscriber) {
+	if s.closed {
+		return
+	}
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+	s.closed = true
+	close(s.ch)
+}
+
+// Subscribe registers a new subscriber and returns a channel that first
+// replays the buffered backlog, collapsed to the most recent event per ID,
+// and then receives live events as they are broadcast. The returned cancel
+// func unregisters the subscriber and closes its channel; it is also called
+// automatically once ctx is done.
+func (b *Broadcaster) Subscribe(ctx context.Context) (<-chan *Progress, func()) {
+	s := &subscriber{ch: make(chan *Progress, len(b.ring))}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(s.ch)
+		return s.ch, func() {}
+	}
+	backlog := b.collapsedBacklogLocked()
+
+	// Lock s.sendMu here, before s is visible to broadcast (which only
+	// happens once it's added to b.subs below), and hand the lock off to
+	// the backlog-replay goroutine to release once it's done. This
+	// guarantees broadcast can't win the race to send a live event ahead
+	// of, or interleaved with, backlog replay.
+	s.sendMu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		defer s.sendMu.Unlock()
+		for _, p := range backlog {
+			if s.closed {
+				return
+			}
+			b.sendLocked(s, p)
+		}
+	}()
+
+	unsubscribed := make(chan struct{})
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(unsubscribed) })
+		b.drop(s)
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-unsubscribed:
+		}
+	}()
+	return s.ch, cancel
+}
+
+// collapsedBacklogLocked returns the buffered events in the order their IDs
+// first appeared, keeping only the most recent event for each ID. Callers
+// must hold b.mu.
+func (b *Broadcaster) collapsedBacklogLocked() []*Progress {
+	latest := make(map[string]*Progress, b.ringLen)
+	order := make([]string, 0, b.ringLen)
+	start := (b.ringPos - b.ringLen + len(b.ring)) % len(b.ring)
+	for i := 0; i < b.ringLen; i++ {
+		p := b.ring[(start+i)%len(b.ring)]
+		if _, ok := latest[p.ID]; !ok {
+			order = append(order, p.ID)
+		}
+		latest[p.ID] = p
+	}
+	out := make([]*Progress, 0, len(order))
+	for _, id := range order {
+		out = append(out, latest[id])
+	}
+	return out
+}
+
+// Close unregisters and closes the channel of every current subscriber and
+// stops accepting new ones.
+func (b *Broadcaster) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for s := range subs {
+		s.sendMu.Lock()
+		if !s.closed {
+			s.closed = true
+			close(s.ch)
+		}
+		s.sendMu.Unlock()
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package progress
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeReader serves pre-baked batches of Progress to a Broadcaster one at
+// a time, then blocks until the test closes done (simulating a Reader
+// that's still open but has nothing new to report).
+type fakeReader struct {
+	mu      sync.Mutex
+	batches [][]*Progress
+	idx     int
+	done    chan struct{}
+}
+
+func (r *fakeReader) Read(ctx context.Context) ([]*Progress, error) {
+	r.mu.Lock()
+	if r.idx < len(r.batches) {
+		b := r.batches[r.idx]
+		r.idx++
+		r.mu.Unlock()
+		return b, nil
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil, context.Canceled
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func waitForRingLen(t *testing.T, b *Broadcaster, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		b.mu.Lock()
+		l := b.ringLen
+		b.mu.Unlock()
+		if l >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("ring never reached length %d", n)
+}
+
+func TestBroadcasterSubscribeReplaysBacklog(t *testing.T) {
+	now := time.Now()
+	fr := &fakeReader{
+		batches: [][]*Progress{
+			{
+				{ID: "a", Timestamp: now, Sys: Status{Action: "a1"}},
+				{ID: "b", Timestamp: now.Add(time.Millisecond), Sys: Status{Action: "b1"}},
+			},
+		},
+		done: make(chan struct{}),
+	}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 10, 50*time.Millisecond)
+	defer b.Close()
+
+	waitForRingLen(t, b, 2)
+
+	ch, cancel := b.Subscribe(context.Background())
+	defer cancel()
+
+	got := map[string]bool{}
+	timeout := time.After(2 * time.Second)
+	for len(got) < 2 {
+		select {
+		case p, ok := <-ch:
+			if !ok {
+				t.Fatalf("channel closed before backlog replay completed, got %v", got)
+			}
+			got[p.ID] = true
+		case <-timeout:
+			t.Fatalf("timed out waiting for backlog replay, got %v", got)
+		}
+	}
+}
+
+func TestBroadcasterDropsSlowSubscriber(t *testing.T) {
+	fr := &fakeReader{done: make(chan struct{})}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 2, 10*time.Millisecond)
+	defer b.Close()
+
+	ch, _ := b.Subscribe(context.Background())
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		b.broadcast([]*Progress{{ID: "x", Timestamp: now.Add(time.Duration(i) * time.Millisecond), Sys: Status{Current: i}}})
+	}
+
+	// Nothing ever reads ch, so once it backs up past its buffer the
+	// subscriber must be dropped and its channel closed within timeout.
+	deadline := time.After(2 * time.Second)
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("expected slow subscriber's channel to be closed")
+		}
+	}
+}
+
+func TestBroadcasterCloseClosesAllSubscribers(t *testing.T) {
+	fr := &fakeReader{done: make(chan struct{})}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 10, time.Second)
+
+	ch1, _ := b.Subscribe(context.Background())
+	ch2, _ := b.Subscribe(context.Background())
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for name, ch := range map[string]<-chan *Progress{"ch1": ch1, "ch2": ch2} {
+		select {
+		case _, ok := <-ch:
+			if ok {
+				t.Errorf("%s: expected channel to be closed", name)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("%s: timed out waiting for channel close", name)
+		}
+	}
+
+	// Subscribing after Close should hand back an already-closed channel.
+	ch3, _ := b.Subscribe(context.Background())
+	select {
+	case _, ok := <-ch3:
+		if ok {
+			t.Error("expected post-Close Subscribe to return a closed channel")
+		}
+	case <-time.After(time.Second):
+		t.Error("timed out waiting for post-Close channel to be closed")
+	}
+}
+
+// TestBroadcasterConcurrentBacklogAndLiveDeliveryDoesNotRace is a
+// regression test for backlog replay (Subscribe's goroutine) racing live
+// delivery (broadcast) for the same freshly-joined subscriber: both used
+// to write to the same channel unsynchronized, so a timeout in either
+// could close the channel out from under the other and panic with "send
+// on closed channel". Run with -race to catch the data race too.
+func TestBroadcasterConcurrentBacklogAndLiveDeliveryDoesNotRace(t *testing.T) {
+	fr := &fakeReader{done: make(chan struct{})}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 50, 50*time.Millisecond)
+	defer b.Close()
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		b.broadcast([]*Progress{{ID: fmt.Sprintf("seed%d", i), Timestamp: now, Sys: Status{Current: i}}})
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+			ch, cancelSub := b.Subscribe(ctx)
+			defer cancelSub()
+			for range ch {
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		b.broadcast([]*Progress{{ID: "live", Timestamp: time.Now(), Sys: Status{Current: i}}})
+	}
+
+	wg.Wait()
+}
+
+// TestBroadcasterCancelDoesNotLeakGoroutine is a regression test: calling
+// the cancel func returned by Subscribe used to drop the subscriber
+// without ever unblocking the goroutine watching ctx.Done(), so a caller
+// using the common context.Background()+defer cancel() pattern (as the
+// rest of this file does) leaked that goroutine for the lifetime of the
+// process.
+func TestBroadcasterCancelDoesNotLeakGoroutine(t *testing.T) {
+	fr := &fakeReader{done: make(chan struct{})}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 10, 50*time.Millisecond)
+	defer b.Close()
+
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 50; i++ {
+		_, cancel := b.Subscribe(context.Background())
+		cancel()
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if runtime.NumGoroutine() <= before+5 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("goroutine count grew from %d to %d after cancel, leak suspected", before, runtime.NumGoroutine())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestBroadcasterLiveEventNeverPrecedesBacklog is a regression test: a
+// subscriber's channel must first drain the ring, then receive live
+// events, but backlog replay and live delivery used to only serialize
+// per-event, letting a live event racing in at Subscribe time be
+// delivered ahead of, or interleaved with, backlog items.
+func TestBroadcasterLiveEventNeverPrecedesBacklog(t *testing.T) {
+	fr := &fakeReader{done: make(chan struct{})}
+	defer close(fr.done)
+
+	b := NewBroadcaster(fr, 100, time.Second)
+	defer b.Close()
+
+	now := time.Now()
+	backlog := make([]*Progress, 0, 64)
+	for i := 0; i < 64; i++ {
+		backlog = append(backlog, &Progress{ID: fmt.Sprintf("seed%d", i), Timestamp: now, Sys: Status{Current: i}})
+	}
+	b.broadcast(backlog)
+	waitForRingLen(t, b, 64)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.broadcast([]*Progress{{ID: "live", Timestamp: time.Now(), Sys: Status{Current: -1}}})
+	}()
+
+	ch, cancel := b.Subscribe(context.Background())
+	defer cancel()
+
+	seenLive := false
+	count := 0
+	for p := range ch {
+		count++
+		if p.ID == "live" {
+			seenLive = true
+		} else if seenLive {
+			t.Fatalf("backlog event %q delivered after the live event", p.ID)
+		}
+		if count == 64 {
+			break
+		}
+	}
+
+	wg.Wait()
+}
@@ -0,0 +1,86 @@
+package progress
+
+import "maps"
+
+// CoalesceFunc merges two writes for the same progress ID that raced each
+// other before either was read: prev is the value currently sitting in the
+// reader's dirty set, next is the value a writer just produced. It returns
+// the value that should replace prev.
+type CoalesceFunc func(prev, next any) any
+
+// WithCoalescer registers fn to merge concurrent writes for id instead of
+// letting the later write silently clobber the earlier one. This matters
+// when several goroutines derive writers from the same context and write
+// to the same ID concurrently, as the solver does when a vertex digest is
+// shared by concurrent jobs: without a coalescer, whichever write reaches
+// the reader last wins and the rest are lost.
+//
+// A *MultiWriter forwards registration to each of its underlying writers,
+// the same way it fans out Write, WriteError, and Close.
+func WithCoalescer(id string, fn CoalesceFunc) WriterOption {
+	var opt WriterOption
+	opt = func(w Writer) {
+		switch pw := w.(type) {
+		case *progressWriter:
+			pw.reader.mu.Lock()
+			if pw.reader.coalescers == nil {
+				pw.reader.coalescers = make(map[string]CoalesceFunc)
+			}
+			pw.reader.coalescers[id] = fn
+			pw.reader.mu.Unlock()
+		case *MultiWriter:
+			pw.mu.Lock()
+			writers := pw.writers
+			pw.mu.Unlock()
+			for _, sub := range writers {
+				opt(sub)
+			}
+		}
+	}
+	return opt
+}
+
+// coalesce applies fn to prev and next's Sys payloads and unions their
+// meta, favoring next's values on key collisions. Callers must hold
+// prev's reader's mu.
+func coalesce(fn CoalesceFunc, prev, next *Progress) *Progress {
+	merged := *next
+	merged.Sys = fn(prev.Sys, next.Sys)
+	if len(prev.meta) > 0 {
+		meta := make(map[string]any, len(prev.meta)+len(next.meta))
+		maps.Copy(meta, prev.meta)
+		maps.Copy(meta, next.meta)
+		merged.meta = meta
+	}
+	return &merged
+}
+
+// CoalesceStatus is a ready-made CoalesceFunc for Status payloads: it
+// keeps the max Current, the earliest Started, the latest Completed, and
+// prev's Error if next didn't report one.
+func CoalesceStatus(prev, next any) any {
+	p, pok := prev.(Status)
+	n, nok := next.(Status)
+	if !nok {
+		return prev
+	}
+	if !pok {
+		return next
+	}
+
+	out := n
+	if p.Current > out.Current {
+		out.Current = p.Current
+	}
+	if p.Started != nil && (out.Started == nil || p.Started.Before(*out.Started)) {
+		out.Started = p.Started
+	}
+	if p.Completed != nil && (out.Completed == nil || p.Completed.After(*out.Completed)) {
+		out.Completed = p.Completed
+	}
+	if out.Error == nil {
+		out.Error = p.Error
+	}
+	out.Canceled = out.Canceled || p.Canceled
+	return out
+}
@@ -0,0 +1,74 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithCoalescerMergesConcurrentWrites(t *testing.T) {
+	pr, ctx, cancel := NewContext(context.Background())
+	defer cancel(nil)
+
+	pw, _, ctx := NewFromContext(ctx, WithCoalescer("shared", CoalesceStatus))
+	pw2, _, _ := NewFromContext(ctx)
+
+	started := time.Now()
+	if err := pw.Write("shared", Status{Started: &started, Current: 1}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := pw2.Write("shared", Status{Started: &started, Current: 5}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	out, err := pr.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("expected a single coalesced event, got %d", len(out))
+	}
+	st, ok := out[0].Sys.(Status)
+	if !ok {
+		t.Fatalf("expected Status payload, got %T", out[0].Sys)
+	}
+	if st.Current != 5 {
+		t.Errorf("expected coalesced Current to keep the max (5), got %d", st.Current)
+	}
+}
+
+// TestWithCoalescerForwardsThroughMultiWriter is a regression test:
+// WithCoalescer only type-switched on *progressWriter, so registering a
+// coalescer against a *MultiWriter (as installed wherever a Broadcaster
+// is joined to a NewContext reader) silently registered nothing.
+func TestWithCoalescerForwardsThroughMultiWriter(t *testing.T) {
+	pr, ctx, cancel := NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := NewFromContext(ctx)
+
+	mw := NewMultiWriter(pw)
+	WithCoalescer("shared", CoalesceStatus)(mw)
+
+	started := time.Now()
+	if err := mw.Write("shared", Status{Started: &started, Current: 1}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := mw.Write("shared", Status{Started: &started, Current: 5}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	out, err := pr.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("expected a single coalesced event, got %d", len(out))
+	}
+	st, ok := out[0].Sys.(Status)
+	if !ok {
+		t.Fatalf("expected Status payload, got %T", out[0].Sys)
+	}
+	if st.Current != 5 {
+		t.Errorf("expected coalesced Current to keep the max (5), got %d", st.Current)
+	}
+}
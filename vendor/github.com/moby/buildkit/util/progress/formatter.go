@@ -0,0 +1,281 @@
+package progress
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Formatter encodes Progress events onto an io.Writer. It is the
+// serialization counterpart to Writer: a Writer decides what gets recorded,
+// a Formatter decides how it is rendered for consumers outside the process
+// (log files, CI systems, telemetry backends).
+type Formatter interface {
+	// Format writes p to w in the formatter's encoding.
+	Format(w io.Writer, p *Progress) error
+	// Close flushes any output buffered across prior Format calls (for
+	// example a summary that can only be produced once the stream ends).
+	Close(w io.Writer) error
+}
+
+// NewFormattedWriter returns a Writer that encodes every write with f and
+// emits the result to w. Closing the returned Writer closes f, not w.
+func NewFormattedWriter(w io.Writer, f Formatter) Writer {
+	return &formattedWriter{w: w, f: f, meta: make(map[string]any)}
+}
+
+type formattedWriter struct {
+	mu   sync.Mutex
+	w    io.Writer
+	f    Formatter
+	meta map[string]any
+	done bool
+}
+
+func (fw *formattedWriter) Write(id string, v any) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.done {
+		return errors.Errorf("writing %s to closed progress writer", id)
+	}
+	return fw.f.Format(fw.w, &Progress{
+		ID:        id,
+		Timestamp: time.Now(),
+		Sys:       v,
+		meta:      fw.meta,
+	})
+}
+
+func (fw *formattedWriter) WriteError(id string, err error) error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.done {
+		return errors.Errorf("writing %s to closed progress writer", id)
+	}
+	return fw.f.Format(fw.w, &Progress{
+		ID:        id,
+		Timestamp: time.Now(),
+		Sys:       statusForError(err),
+		meta:      fw.meta,
+	})
+}
+
+func (fw *formattedWriter) Close() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.done = true
+	return fw.f.Close(fw.w)
+}
+
+// JSONLinesFormatter renders each Progress as a single line of JSON,
+// newline-delimited so the output can be streamed and tailed.
+type JSONLinesFormatter struct{}
+
+type jsonLineProgress struct {
+	ID        string         `json:"id"`
+	Timestamp time.Time      `json:"timestamp"`
+	Action    string         `json:"action,omitempty"`
+	Current   int            `json:"current,omitempty"`
+	Total     int            `json:"total,omitempty"`
+	Started   *time.Time     `json:"started,omitempty"`
+	Completed *time.Time     `json:"completed,omitempty"`
+	Error     *jsonLineError `json:"error,omitempty"`
+	Canceled  bool           `json:"canceled,omitempty"`
+	Sys       any            `json:"sys,omitempty"`
+	Meta      map[string]any `json:"meta,omitempty"`
+}
+
+type jsonLineError struct {
+	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
+}
+
+func (JSONLinesFormatter) Format(w io.Writer, p *Progress) error {
+	line := jsonLineProgress{
+		ID:        p.ID,
+		Timestamp: p.Timestamp,
+		Meta:      p.meta,
+	}
+	if st, ok := p.Sys.(Status); ok {
+		line.Action = st.Action
+		line.Current = st.Current
+		line.Total = st.Total
+		line.Started = st.Started
+		line.Completed = st.Completed
+		line.Canceled = st.Canceled
+		if st.Error != nil {
+			line.Error = &jsonLineError{Message: st.Error.Error()}
+			if pe, ok := st.Error.(*ProgressError); ok {
+				line.Error.Code = pe.Code
+			}
+		}
+	} else {
+		line.Sys = p.Sys
+	}
+
+	b, err := json.Marshal(line)
+	if err != nil {
+		return errors.Wrapf(err, "marshaling progress %s", p.ID)
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+func (JSONLinesFormatter) Close(io.Writer) error {
+	return nil
+}
+
+// OTLPFormatter renders Status transitions as OpenTelemetry-shaped span
+// start/end events, one JSON object per line, with the progress ID as the
+// span name and its meta as attributes. It does not depend on an OTLP SDK
+// or exporter; callers that need an actual OTLP pipeline can feed these
+// events into one.
+type OTLPFormatter struct{}
+
+type otlpSpanEvent struct {
+	Name       string         `json:"name"`
+	Event      string         `json:"event"` // "start" or "end"
+	Time       time.Time      `json:"time"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+}
+
+func (OTLPFormatter) Format(w io.Writer, p *Progress) error {
+	st, ok := p.Sys.(Status)
+	if !ok {
+		return nil
+	}
+
+	var ev otlpSpanEvent
+	switch {
+	case st.Completed != nil:
+		attrs := p.meta
+		if st.Error != nil || st.Canceled {
+			attrs = make(map[string]any, len(p.meta)+2)
+			maps.Copy(attrs, p.meta)
+			if st.Error != nil {
+				attrs["error"] = st.Error.Error()
+			}
+			if st.Canceled {
+				attrs["canceled"] = true
+			}
+		}
+		ev = otlpSpanEvent{Name: p.ID, Event: "end", Time: *st.Completed, Attributes: attrs}
+	case st.Started != nil:
+		ev = otlpSpanEvent{Name: p.ID, Event: "start", Time: *st.Started, Attributes: p.meta}
+	default:
+		return nil
+	}
+
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return errors.Wrapf(err, "marshaling span event %s", p.ID)
+	}
+	b = append(b, '\n')
+	_, err = w.Write(b)
+	return err
+}
+
+func (OTLPFormatter) Close(io.Writer) error {
+	return nil
+}
+
+// XUnitFormatter accumulates completed Status entries per ID and, on
+// Close, flushes them as a single JUnit/xunit <testsuite> document. It must
+// not be shared between unrelated streams since it holds state across
+// Format calls.
+type XUnitFormatter struct {
+	mu    sync.Mutex
+	order []string
+	cases map[string]xunitCase
+}
+
+type xunitCase struct {
+	started   *time.Time
+	completed *time.Time
+	errMsg    string
+}
+
+// NewXUnitFormatter returns a ready-to-use XUnitFormatter.
+func NewXUnitFormatter() *XUnitFormatter {
+	return &XUnitFormatter{cases: make(map[string]xunitCase)}
+}
+
+func (f *XUnitFormatter) Format(_ io.Writer, p *Progress) error {
+	st, ok := p.Sys.(Status)
+	if !ok {
+		return nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	c, ok := f.cases[p.ID]
+	if !ok {
+		f.order = append(f.order, p.ID)
+	}
+	if st.Started != nil {
+		c.started = st.Started
+	}
+	if st.Completed != nil {
+		c.completed = st.Completed
+	}
+	if st.Error != nil {
+		c.errMsg = st.Error.Error()
+	}
+	f.cases[p.ID] = c
+	return nil
+}
+
+type xunitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+type xunitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *xunitFailure `xml:"failure,omitempty"`
+}
+
+type xunitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	TestCases []xunitTestCase `xml:"testcase"`
+}
+
+func (f *XUnitFormatter) Close(w io.Writer) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var suite xunitTestSuite
+	for _, id := range f.order {
+		c := f.cases[id]
+		if c.completed == nil {
+			continue
+		}
+		var dur float64
+		if c.started != nil && c.completed != nil {
+			dur = c.completed.Sub(*c.started).Seconds()
+		}
+		tc := xunitTestCase{Name: id, Time: dur}
+		if c.errMsg != "" {
+			tc.Failure = &xunitFailure{Message: c.errMsg}
+			suite.Failures++
+		}
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	b, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "marshaling xunit testsuite")
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,140 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJSONLinesFormatterWritesOneLinePerEvent(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFormattedWriter(&buf, JSONLinesFormatter{})
+
+	if err := w.Write("step1", Status{Action: "running", Current: 1, Total: 2}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 line, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], `"id":"step1"`) || !strings.Contains(lines[0], `"action":"running"`) {
+		t.Errorf("unexpected line: %s", lines[0])
+	}
+}
+
+func TestXUnitFormatterFlushesTestSuiteOnClose(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFormattedWriter(&buf, NewXUnitFormatter())
+
+	start := time.Now()
+	end := start.Add(2 * time.Second)
+
+	if err := w.Write("step1", Status{Started: &start}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Write("step1", Status{Started: &start, Completed: &end}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := w.Write("step2", Status{Started: &start, Completed: &end, Error: newTestError("boom")}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, `<testsuite tests="2" failures="1">`) {
+		t.Errorf("expected testsuite summary, got: %s", out)
+	}
+	if !strings.Contains(out, `name="step1"`) || !strings.Contains(out, `message="boom"`) {
+		t.Errorf("expected per-case entries, got: %s", out)
+	}
+}
+
+func TestOTLPFormatterEmitsStartAndEndSpanEvents(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFormattedWriter(&buf, OTLPFormatter{})
+
+	started := time.Now()
+	if err := w.Write("step1", Status{Started: &started}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	completed := started.Add(time.Second)
+	if err := w.Write("step1", Status{Started: &started, Completed: &completed}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 span events, got %d: %q", len(lines), buf.String())
+	}
+	if !strings.Contains(lines[0], `"name":"step1"`) || !strings.Contains(lines[0], `"event":"start"`) {
+		t.Errorf("unexpected start event: %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"name":"step1"`) || !strings.Contains(lines[1], `"event":"end"`) {
+		t.Errorf("unexpected end event: %s", lines[1])
+	}
+}
+
+func TestOTLPFormatterEndEventCarriesErrorAndCanceledAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFormattedWriter(&buf, OTLPFormatter{})
+
+	if err := w.WriteError("step1", context.Canceled); err != nil {
+		t.Fatalf("WriteError: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	out := strings.TrimSpace(buf.String())
+	if !strings.Contains(out, `"event":"end"`) {
+		t.Fatalf("expected an end event, got: %s", out)
+	}
+	if !strings.Contains(out, `"canceled":true`) {
+		t.Errorf("expected the canceled attribute to be set, got: %s", out)
+	}
+	if !strings.Contains(out, `"error":"context canceled"`) {
+		t.Errorf("expected the error attribute to carry the cause, got: %s", out)
+	}
+}
+
+// TestOTLPFormatterIncludesWithMetadataAttributes is a regression test:
+// formattedWriter used to have no meta field at all, so WithMetadata
+// silently did nothing for a Writer created via NewFormattedWriter and
+// OTLPFormatter's "meta as attributes" claim was unreachable.
+func TestOTLPFormatterIncludesWithMetadataAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewFormattedWriter(&buf, OTLPFormatter{})
+	WithMetadata("service", "buildkitd")(w)
+
+	started := time.Now()
+	if err := w.Write("step1", Status{Started: &started}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	out := strings.TrimSpace(buf.String())
+	if !strings.Contains(out, `"service":"buildkitd"`) {
+		t.Errorf("expected the service attribute to be set, got: %s", out)
+	}
+}
+
+type testError string
+
+func newTestError(msg string) error { return testError(msg) }
+
+func (e testError) Error() string { return string(e) }
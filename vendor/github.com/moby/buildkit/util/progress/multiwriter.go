@@ -0,0 +1,72 @@
+package progress
+
+import "sync"
+
+// MultiWriter is a Writer that fans out every call to a set of underlying
+// Writers. Like a *progressWriter, a *MultiWriter can be stored in a
+// Context via WithProgress, which lets FromContext hand callers a single
+// Writer that feeds several independent consumers (for example a primary
+// NewContext Reader alongside a Broadcaster) from one set of writes.
+type MultiWriter struct {
+	mu      sync.Mutex
+	writers []Writer
+	meta    map[string]any
+}
+
+// NewMultiWriter returns a MultiWriter that forwards every Write and Close
+// call to each of writers.
+func NewMultiWriter(writers ...Writer) *MultiWriter {
+	return &MultiWriter{
+		writers: writers,
+		meta:    make(map[string]any),
+	}
+}
+
+// Add registers an additional Writer to fan out to.
+func (mw *MultiWriter) Add(w Writer) {
+	mw.mu.Lock()
+	mw.writers = append(mw.writers, w)
+	mw.mu.Unlock()
+}
+
+func (mw *MultiWriter) Write(id string, v any) error {
+	mw.mu.Lock()
+	writers := mw.writers
+	mw.mu.Unlock()
+
+	var firstErr error
+	for _, w := range writers {
+		if err := w.Write(id, v); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (mw *MultiWriter) WriteError(id string, cause error) error {
+	mw.mu.Lock()
+	writers := mw.writers
+	mw.mu.Unlock()
+
+	var firstErr error
+	for _, w := range writers {
+		if err := w.WriteError(id, cause); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (mw *MultiWriter) Close() error {
+	mw.mu.Lock()
+	writers := mw.writers
+	mw.mu.Unlock()
+
+	var firstErr error
+	for _, w := range writers {
+		if err := w.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
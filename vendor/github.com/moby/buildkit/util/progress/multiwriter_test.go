@@ -0,0 +1,78 @@
+package progress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type recordingWriter struct {
+	writes int
+	errors int
+	closed bool
+}
+
+func (w *recordingWriter) Write(string, any) error {
+	w.writes++
+	return nil
+}
+
+func (w *recordingWriter) WriteError(string, error) error {
+	w.errors++
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	w.closed = true
+	return nil
+}
+
+func TestMultiWriterFansOut(t *testing.T) {
+	a, b := &recordingWriter{}, &recordingWriter{}
+	mw := NewMultiWriter(a, b)
+
+	if err := mw.Write("id", Status{}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := mw.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for name, w := range map[string]*recordingWriter{"a": a, "b": b} {
+		if w.writes != 1 {
+			t.Errorf("%s: expected 1 write, got %d", name, w.writes)
+		}
+		if !w.closed {
+			t.Errorf("%s: expected Close to be forwarded", name)
+		}
+	}
+}
+
+func TestMultiWriterWriteErrorFansOut(t *testing.T) {
+	a, b := &recordingWriter{}, &recordingWriter{}
+	mw := NewMultiWriter(a, b)
+
+	if err := mw.WriteError("id", errors.New("boom")); err != nil {
+		t.Fatalf("WriteError: %v", err)
+	}
+
+	for name, w := range map[string]*recordingWriter{"a": a, "b": b} {
+		if w.errors != 1 {
+			t.Errorf("%s: expected 1 error write, got %d", name, w.errors)
+		}
+	}
+}
+
+func TestMultiWriterFromContext(t *testing.T) {
+	mw := NewMultiWriter()
+	ctx := WithProgress(context.Background(), mw)
+
+	pw, ok, _ := NewFromContext(ctx)
+	if !ok {
+		t.Fatal("expected a Writer to be found in context")
+	}
+	if pw != Writer(mw) {
+		t.Fatalf("expected FromContext to return the *MultiWriter itself, got %T", pw)
+	}
+}
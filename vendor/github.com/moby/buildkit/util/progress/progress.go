@@ -75,6 +75,9 @@ func WithMetadata(key string, val any) WriterOption {
 		if pw, ok := w.(*MultiWriter); ok {
 			pw.meta[key] = val
 		}
+		if fw, ok := w.(*formattedWriter); ok {
+			fw.meta[key] = val
+		}
 	}
 }
 
@@ -85,6 +88,11 @@ type Controller interface {
 
 type Writer interface {
 	Write(id string, value any) error
+	// WriteError records err as the terminal event for id: it marks the
+	// event Completed, sets Status.Error (wrapping err in a ProgressError
+	// if it isn't one already), and sets Status.Canceled when err wraps
+	// context.Canceled.
+	WriteError(id string, err error) error
 	Close() error
 }
 
@@ -105,14 +113,17 @@ type Status struct {
 	Total     int
 	Started   *time.Time
 	Completed *time.Time
+	Error     error
+	Canceled  bool
 }
 
 type progressReader struct {
-	ctx     context.Context
-	cond    *sync.Cond
-	mu      sync.Mutex
-	writers map[*progressWriter]struct{}
-	dirty   map[string]*Progress
+	ctx        context.Context
+	cond       *sync.Cond
+	mu         sync.Mutex
+	writers    map[*progressWriter]struct{}
+	dirty      map[string]*Progress
+	coalescers map[string]CoalesceFunc
 }
 
 func (pr *progressReader) Read(ctx context.Context) ([]*Progress, error) {
@@ -252,12 +263,21 @@ func (pw *progressWriter) WriteRawProgress(p *Progress) error {
 
 func (pw *progressWriter) writeRawProgress(p *Progress) error {
 	pw.reader.mu.Lock()
+	if fn, ok := pw.reader.coalescers[p.ID]; ok {
+		if prev, ok := pw.reader.dirty[p.ID]; ok {
+			p = coalesce(fn, prev, p)
+		}
+	}
 	pw.reader.dirty[p.ID] = p
 	pw.reader.cond.Broadcast()
 	pw.reader.mu.Unlock()
 	return nil
 }
 
+func (pw *progressWriter) WriteError(id string, err error) error {
+	return pw.Write(id, statusForError(err))
+}
+
 func (pw *progressWriter) Close() error {
 	pw.reader.mu.Lock()
 	delete(pw.reader.writers, pw)
@@ -278,10 +298,26 @@ func (pw *noOpWriter) Write(_ string, _ any) error {
 	return nil
 }
 
+func (pw *noOpWriter) WriteError(_ string, _ error) error {
+	return nil
+}
+
 func (pw *noOpWriter) Close() error {
 	return nil
 }
 
+// statusForError builds the terminal Status for a failed or canceled step:
+// Completed is set to now, Error wraps err (see wrapError), and Canceled
+// reflects whether err wraps context.Canceled.
+func statusForError(err error) Status {
+	now := time.Now()
+	return Status{
+		Completed: &now,
+		Error:     wrapError(err),
+		Canceled:  errors.Is(err, context.Canceled),
+	}
+}
+
 func OneOff(ctx context.Context, id string) func(err error) error {
 	pw, _, _ := NewFromContext(ctx)
 	now := time.Now()
@@ -290,9 +326,12 @@ func OneOff(ctx context.Context, id string) func(err error) error {
 	}
 	pw.Write(id, st)
 	return func(err error) error {
-		// TODO: set error on status
 		now := time.Now()
 		st.Completed = &now
+		if err != nil {
+			st.Error = wrapError(err)
+			st.Canceled = errors.Is(err, context.Canceled)
+		}
 		pw.Write(id, st)
 		pw.Close()
 		return err
@@ -0,0 +1,31 @@
+package progress
+
+// ProgressError is a serializable wrapper around an error attached to a
+// Status, suitable for crossing a process boundary (gRPC, JSON over a
+// socket) where the original error type and its unexported state would
+// not survive encoding.
+type ProgressError struct {
+	Message string
+	Code    string
+	Cause   error
+}
+
+func (e *ProgressError) Error() string {
+	return e.Message
+}
+
+func (e *ProgressError) Unwrap() error {
+	return e.Cause
+}
+
+// wrapError returns err unchanged if it is already a *ProgressError,
+// otherwise wraps it in one so every Status.Error is transport-safe.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if pe, ok := err.(*ProgressError); ok {
+		return pe
+	}
+	return &ProgressError{Message: err.Error(), Cause: err}
+}
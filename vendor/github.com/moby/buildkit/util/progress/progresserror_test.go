@@ -0,0 +1,80 @@
+package progress
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestOneOffRecordsErrorOnStatus(t *testing.T) {
+	pr, ctx, cancel := NewContext(context.Background())
+	defer cancel(nil)
+
+	done := OneOff(ctx, "step1")
+	if err := done(errors.New("boom")); err == nil {
+		t.Fatal("expected OneOff to return the error it was given")
+	}
+
+	var st Status
+	for {
+		out, err := pr.Read(context.Background())
+		if err != nil {
+			t.Fatalf("Read: %v", err)
+		}
+		found := false
+		for _, p := range out {
+			if p.ID == "step1" {
+				st, found = p.Sys.(Status), true
+			}
+		}
+		if found && st.Completed != nil {
+			break
+		}
+	}
+
+	if st.Error == nil {
+		t.Fatal("expected Status.Error to be set")
+	}
+	if st.Error.Error() != "boom" {
+		t.Errorf("expected error message %q, got %q", "boom", st.Error.Error())
+	}
+	if st.Canceled {
+		t.Error("expected Canceled to be false for a plain error")
+	}
+}
+
+func TestOneOffMarksCanceledFromContextCanceled(t *testing.T) {
+	pr, ctx, cancel := NewContext(context.Background())
+	defer cancel(nil)
+
+	done := OneOff(ctx, "step1")
+	done(context.Canceled)
+
+	out, err := pr.Read(context.Background())
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(out))
+	}
+	st, ok := out[0].Sys.(Status)
+	if !ok {
+		t.Fatalf("expected Status payload, got %T", out[0].Sys)
+	}
+	if !st.Canceled {
+		t.Error("expected Canceled to be true when err wraps context.Canceled")
+	}
+}
+
+func TestWrapErrorPreservesExistingProgressError(t *testing.T) {
+	pe := &ProgressError{Message: "boom", Code: "E1"}
+	if wrapError(pe) != error(pe) {
+		t.Fatal("expected wrapError to return the same *ProgressError unchanged")
+	}
+
+	wrapped := wrapError(errors.New("boom"))
+	if _, ok := wrapped.(*ProgressError); !ok {
+		t.Fatalf("expected a plain error to be wrapped, got %T", wrapped)
+	}
+}
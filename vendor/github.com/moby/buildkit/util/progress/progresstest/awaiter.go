@@ -0,0 +1,118 @@
+// Package progresstest provides test helpers for asserting on the state of
+// a progress.Reader without polling or hand-rolled channel draining.
+package progresstest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/util/progress"
+	"github.com/pkg/errors"
+)
+
+// Awaiter observes every Progress read from a Reader and lets tests block
+// until a set of Expectations holds against the latest state, instead of
+// sleeping or hand-rolling channel drains.
+type Awaiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	latest map[string]*progress.Progress
+	err    error
+	done   bool
+}
+
+// NewAwaiter starts draining r in the background and returns an Awaiter
+// that can be queried with Await. It is safe to call Await concurrently
+// from multiple goroutines.
+func NewAwaiter(r progress.Reader) *Awaiter {
+	a := &Awaiter{latest: make(map[string]*progress.Progress)}
+	a.cond = sync.NewCond(&a.mu)
+	go a.run(r)
+	return a
+}
+
+func (a *Awaiter) run(r progress.Reader) {
+	ctx := context.Background()
+	for {
+		ps, err := r.Read(ctx)
+		a.mu.Lock()
+		if err != nil {
+			a.err = err
+			a.done = true
+			a.cond.Broadcast()
+			a.mu.Unlock()
+			return
+		}
+		for _, p := range ps {
+			a.latest[p.ID] = p
+		}
+		a.cond.Broadcast()
+		a.mu.Unlock()
+	}
+}
+
+// Await blocks until every expectation holds against the latest observed
+// Progress for each ID, returning nil as soon as they do. It returns an
+// error if ctx is done first, if a WithinDuration expectation's own
+// budget elapses, or if the underlying Reader reaches EOF (or errors)
+// before the expectations are met.
+func (a *Awaiter) Await(ctx context.Context, expectations ...Expectation) error {
+	started := time.Now()
+	exp := AllOf(expectations...)
+
+	// WithinDuration expectations must fail as soon as their own budget
+	// elapses, not merely whenever the next Progress happens to arrive or
+	// ctx happens to be done. Wake the wait loop at each such deadline so
+	// it gets a chance to notice.
+	var timers []*time.Timer
+	for _, d := range exp.deadlines() {
+		timers = append(timers, time.AfterFunc(d, func() {
+			a.mu.Lock()
+			a.cond.Broadcast()
+			a.mu.Unlock()
+		}))
+	}
+	defer func() {
+		for _, t := range timers {
+			t.Stop()
+		}
+	}()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			a.mu.Lock()
+			a.cond.Broadcast()
+			a.mu.Unlock()
+		case <-done:
+		}
+	}()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		state := evalState{latest: a.latest, started: started}
+		r := exp.eval(state)
+		if r.ok {
+			return nil
+		}
+		if r.failed {
+			return errors.New("progresstest: expectation can never be satisfied (a WithinDuration deadline elapsed)")
+		}
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		default:
+		}
+		if a.done {
+			if a.err != nil {
+				return a.err
+			}
+			return errors.New("progresstest: stream ended before expectations were satisfied")
+		}
+		a.cond.Wait()
+	}
+}
@@ -0,0 +1,70 @@
+package progresstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/util/progress"
+)
+
+func TestAwaiterBlocksUntilExpectationsAreMet(t *testing.T) {
+	pr, ctx, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := progress.NewFromContext(ctx)
+
+	a := NewAwaiter(pr)
+
+	go func() {
+		now := time.Now()
+		pw.Write("step1", progress.Status{Started: &now})
+		time.Sleep(10 * time.Millisecond)
+		completed := time.Now()
+		pw.Write("step1", progress.Status{Started: &now, Completed: &completed})
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+	if err := a.Await(waitCtx, Completed("step1")); err != nil {
+		t.Fatalf("Await: %v", err)
+	}
+}
+
+func TestAwaiterTimesOutWhenExpectationNeverHolds(t *testing.T) {
+	pr, ctx, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := progress.NewFromContext(ctx)
+
+	a := NewAwaiter(pr)
+
+	now := time.Now()
+	pw.Write("step1", progress.Status{Started: &now})
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer waitCancel()
+	if err := a.Await(waitCtx, Completed("step1")); err == nil {
+		t.Fatal("expected Await to time out, got nil error")
+	}
+}
+
+func TestAllOfAndAnyOf(t *testing.T) {
+	pr, ctx, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := progress.NewFromContext(ctx)
+
+	a := NewAwaiter(pr)
+
+	now := time.Now()
+	pw.Write("a", progress.Status{Started: &now, Completed: &now})
+	pw.Write("b", progress.Status{Started: &now})
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer waitCancel()
+
+	if err := a.Await(waitCtx, AllOf(Completed("a"), Started("b"))); err != nil {
+		t.Fatalf("AllOf: %v", err)
+	}
+	if err := a.Await(waitCtx, AnyOf(Completed("b"), Started("b"))); err != nil {
+		t.Fatalf("AnyOf: %v", err)
+	}
+}
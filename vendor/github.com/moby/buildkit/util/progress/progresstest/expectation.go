@@ -0,0 +1,174 @@
+package progresstest
+
+import (
+	"time"
+
+	"github.com/moby/buildkit/util/progress"
+)
+
+// evalState is the snapshot an Expectation is evaluated against: the
+// latest Progress observed per ID, and when the enclosing Await call
+// started (used by WithinDuration).
+type evalState struct {
+	latest  map[string]*progress.Progress
+	started time.Time
+}
+
+// evalResult is an Expectation's verdict against an evalState: ok reports
+// whether it currently holds, and failed reports whether it can now never
+// hold, e.g. because a WithinDuration budget has elapsed.
+type evalResult struct {
+	ok     bool
+	failed bool
+}
+
+// Expectation is a composable predicate over the latest observed Progress
+// per ID, used with Awaiter.Await. Build one with Started, Completed,
+// MetaEquals, AllOf, AnyOf, or WithinDuration.
+type Expectation interface {
+	eval(s evalState) evalResult
+	// deadlines returns every duration, measured from the enclosing
+	// Await's start, that a WithinDuration anywhere in this expectation
+	// needs Await to wake up and re-check at, even if no new Progress
+	// arrives in the meantime.
+	deadlines() []time.Duration
+}
+
+type simpleExpectation func(s evalState) bool
+
+func (f simpleExpectation) eval(s evalState) evalResult {
+	return evalResult{ok: f(s)}
+}
+
+func (simpleExpectation) deadlines() []time.Duration { return nil }
+
+// Started reports whether id has a Status with a non-nil Started time.
+func Started(id string) Expectation {
+	return simpleExpectation(func(s evalState) bool {
+		st, ok := statusFor(s, id)
+		return ok && st.Started != nil
+	})
+}
+
+// Completed reports whether id has a Status with a non-nil Completed time.
+func Completed(id string) Expectation {
+	return simpleExpectation(func(s evalState) bool {
+		st, ok := statusFor(s, id)
+		return ok && st.Completed != nil
+	})
+}
+
+// MetaEquals reports whether id's latest Progress carries a meta entry
+// key equal to val.
+func MetaEquals(id, key string, val any) Expectation {
+	return simpleExpectation(func(s evalState) bool {
+		p, ok := s.latest[id]
+		if !ok {
+			return false
+		}
+		v, ok := p.Meta(key)
+		return ok && v == val
+	})
+}
+
+type allOfExpectation []Expectation
+
+// AllOf is satisfied once every one of exps is satisfied, and fails
+// permanently as soon as any of exps does.
+func AllOf(exps ...Expectation) Expectation {
+	return allOfExpectation(exps)
+}
+
+func (a allOfExpectation) eval(s evalState) evalResult {
+	ok := true
+	failed := false
+	for _, exp := range a {
+		r := exp.eval(s)
+		if !r.ok {
+			ok = false
+		}
+		if r.failed {
+			failed = true
+		}
+	}
+	return evalResult{ok: ok, failed: failed && !ok}
+}
+
+func (a allOfExpectation) deadlines() []time.Duration {
+	var out []time.Duration
+	for _, exp := range a {
+		out = append(out, exp.deadlines()...)
+	}
+	return out
+}
+
+type anyOfExpectation []Expectation
+
+// AnyOf is satisfied once any one of exps is satisfied, and only fails
+// permanently once every one of exps does.
+func AnyOf(exps ...Expectation) Expectation {
+	return anyOfExpectation(exps)
+}
+
+func (a anyOfExpectation) eval(s evalState) evalResult {
+	ok := false
+	allFailed := true
+	for _, exp := range a {
+		r := exp.eval(s)
+		if r.ok {
+			ok = true
+		}
+		if !r.failed {
+			allFailed = false
+		}
+	}
+	return evalResult{ok: ok, failed: allFailed && !ok}
+}
+
+func (a anyOfExpectation) deadlines() []time.Duration {
+	var out []time.Duration
+	for _, exp := range a {
+		out = append(out, exp.deadlines()...)
+	}
+	return out
+}
+
+type withinDurationExpectation struct {
+	d   time.Duration
+	exp Expectation
+}
+
+// WithinDuration requires exp to become satisfied no later than d after
+// the enclosing Await call started. Await wakes up and re-checks at d
+// even if no new Progress arrives in the meantime, and once d has
+// elapsed without exp holding, the expectation fails permanently: Await
+// returns an error immediately rather than continuing to block on its
+// own ctx.
+func WithinDuration(d time.Duration, exp Expectation) Expectation {
+	return withinDurationExpectation{d: d, exp: exp}
+}
+
+func (w withinDurationExpectation) eval(s evalState) evalResult {
+	r := w.exp.eval(s)
+	elapsed := time.Since(s.started)
+	if r.ok && elapsed <= w.d {
+		return evalResult{ok: true}
+	}
+	if elapsed > w.d {
+		return evalResult{ok: false, failed: true}
+	}
+	return evalResult{ok: false, failed: r.failed}
+}
+
+func (w withinDurationExpectation) deadlines() []time.Duration {
+	return append([]time.Duration{w.d}, w.exp.deadlines()...)
+}
+
+func statusFor(s evalState, id string) (progress.Status, bool) {
+	p, ok := s.latest[id]
+	if !ok {
+		return progress.Status{}, false
+	}
+	st, ok := p.Sys.(progress.Status)
+	return st, ok
+}
@@ -0,0 +1,79 @@
+package progresstest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/util/progress"
+)
+
+func TestWithinDurationSucceedsBeforeDeadline(t *testing.T) {
+	pr, ctx, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := progress.NewFromContext(ctx)
+
+	a := NewAwaiter(pr)
+
+	go func() {
+		now := time.Now()
+		pw.Write("step1", progress.Status{Completed: &now})
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer waitCancel()
+	if err := a.Await(waitCtx, WithinDuration(500*time.Millisecond, Completed("step1"))); err != nil {
+		t.Fatalf("Await: %v", err)
+	}
+}
+
+// TestWithinDurationFailsEarlyWhenDeadlineElapses is a regression test:
+// WithinDuration used to only be rechecked whenever Await happened to wake
+// up for some other reason (a new Progress event or ctx being done), so a
+// short WithinDuration budget nested under a much longer outer ctx never
+// actually cut Await short.
+func TestWithinDurationFailsEarlyWhenDeadlineElapses(t *testing.T) {
+	pr, _, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+
+	a := NewAwaiter(pr)
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer waitCancel()
+
+	start := time.Now()
+	err := a.Await(waitCtx, WithinDuration(50*time.Millisecond, Completed("never")))
+	elapsed := time.Since(start)
+
+	if err == nil {
+		t.Fatal("expected Await to fail once the WithinDuration budget elapsed")
+	}
+	if elapsed > 500*time.Millisecond {
+		t.Fatalf("expected Await to return shortly after the 50ms budget, took %s", elapsed)
+	}
+}
+
+func TestWithinDurationInsideAnyOfDoesNotSinkOtherBranches(t *testing.T) {
+	pr, ctx, cancel := progress.NewContext(context.Background())
+	defer cancel(nil)
+	pw, _, _ := progress.NewFromContext(ctx)
+
+	a := NewAwaiter(pr)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		now := time.Now()
+		pw.Write("slow", progress.Status{Completed: &now})
+	}()
+
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer waitCancel()
+
+	err := a.Await(waitCtx, AnyOf(
+		WithinDuration(10*time.Millisecond, Completed("fast")),
+		Completed("slow"),
+	))
+	if err != nil {
+		t.Fatalf("expected the non-deadline branch to still satisfy AnyOf, got: %v", err)
+	}
+}